@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+)
+
+// fixVsock runs every step needed to bring /dev/vsock into a state
+// checkVsock is happy with: granting cap_net_bind_service to the crc
+// executable given as args[0], loading the vhost_vsock kernel module,
+// and fixing the device's owning group and permissions until the udev
+// rule installed by this package takes over on the next boot. It is
+// exposed as a single action, rather than one per step, so a caller
+// going through pkexec only has to authenticate once.
+func fixVsock(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("fix-vsock requires exactly one argument: the path to the crc executable")
+	}
+	executable := args[0]
+
+	if err := run("setcap", "cap_net_bind_service=+eip", executable); err != nil {
+		return err
+	}
+	if err := run("modprobe", "vhost_vsock"); err != nil {
+		return err
+	}
+	group, err := user.LookupGroup("libvirt")
+	if err != nil {
+		return fmt.Errorf("libvirt group does not exist: %w", err)
+	}
+	if err := run("chown", fmt.Sprintf("root:%s", group.Name), vsockDevice); err != nil {
+		return err
+	}
+	return run("chmod", "g+rw", vsockDevice)
+}
+
+// installLibvirtNetwork defines (and autostarts) the 'crc' libvirt
+// network from the XML definition at args[0].
+func installLibvirtNetwork(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("crc-setup-helper-network requires exactly one argument: the path to the network XML definition")
+	}
+	if err := run("virsh", "net-define", args[0]); err != nil {
+		return err
+	}
+	return run("virsh", "net-autostart", "crc")
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,44 @@
+// crc-setup-helper is a small privileged helper invoked via pkexec (see
+// org.crc.setup.policy) so that `crc setup` can perform its one-time
+// host configuration with a single polkit authentication prompt instead
+// of shelling out to sudo/setcap/chown/chmod/virsh separately for each
+// step.
+//
+// It is a multi-call binary: which of its two actions it performs is
+// fixed by the name it was invoked as (crc-setup-helper or
+// crc-setup-helper-network, the latter a symlink installed alongside
+// it), not by an argv flag. pkexec decides which polkit action to
+// authorize from the executable path alone, so the same path can't be
+// shared between two distinct actions without the authorization being
+// ambiguous between them.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const vsockDevice = "/dev/vsock"
+
+func main() {
+	args := os.Args[1:]
+
+	var err error
+	switch filepath.Base(os.Args[0]) {
+	case "crc-setup-helper":
+		err = fixVsock(args)
+	case "crc-setup-helper-network":
+		err = installLibvirtNetwork(args)
+	default:
+		err = fmt.Errorf("unrecognized helper name %q", os.Args[0])
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "crc-setup-helper:", err)
+	os.Exit(1)
+}
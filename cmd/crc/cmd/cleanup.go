@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/spf13/cobra"
+)
+
+var cleanupOutputFormat string
+
+func init() {
+	cleanupCmd.Flags().StringVar(&cleanupOutputFormat, "output", "text", `Output format for the cleanup plan: "text" or "json"`)
+	rootCmd.AddCommand(cleanupCmd)
+}
+
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Undo 'crc setup' changes to the host",
+	Long:  `Undo the host configuration changes made by 'crc setup', printing the plan before anything destructive runs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCleanup()
+	},
+}
+
+func runCleanup() error {
+	planner := preflight.NewPlanner(preflight.AllPreflightChecks())
+
+	if err := printCleanupPlan(planner.Plan(), cleanupOutputFormat); err != nil {
+		return err
+	}
+
+	return planner.Execute()
+}
+
+func printCleanupPlan(plan []preflight.PlannedCheck, format string) error {
+	if format == "json" {
+		return json.NewEncoder(os.Stdout).Encode(plan)
+	}
+	for _, check := range plan {
+		fmt.Printf("%s:\n", check.Description)
+		for _, action := range check.Actions {
+			reversible := "not reversible"
+			if action.Reversible {
+				reversible = "reversible"
+			}
+			fmt.Printf("  - %s (%s)\n", action.Description, reversible)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Package cmd implements the crc command-line interface.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "crc",
+	Short: "CodeReady Containers",
+	Long:  `CodeReady Containers brings a minimal OpenShift 4 cluster to your laptop or desktop computer.`,
+}
+
+// Execute runs the crc command tree, exiting the process with a
+// non-zero status if the selected command returns an error.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
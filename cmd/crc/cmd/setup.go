@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/spf13/cobra"
+)
+
+var setupOutputFormat string
+
+func init() {
+	setupCmd.Flags().StringVar(&setupOutputFormat, "output", "text", `Output format for preflight results: "text", "json" or "ndjson"`)
+	rootCmd.AddCommand(setupCmd)
+}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Set up the host for the CRC virtual machine",
+	Long:  `Set up the host, checking and fixing everything the CRC virtual machine requires to run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSetup()
+	},
+}
+
+func runSetup() error {
+	notifyUnrecoveredCleanup()
+
+	reporter, flush := newSetupReporter(setupOutputFormat)
+	results := preflight.RunPreflightChecks(reporter)
+	if flush != nil {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	for _, result := range results {
+		if result.Status == preflight.StatusFail {
+			return fmt.Errorf("preflight check %q failed: %s", result.ConfigKeySuffix, result.Error)
+		}
+	}
+	return nil
+}
+
+// notifyUnrecoveredCleanup surfaces the reversible actions recorded by
+// a previous `crc cleanup` run, so a user who cleaned up and is now
+// running `crc setup` again knows those changes are about to be redone,
+// then clears the recorded state since this setup run is about to
+// recreate them.
+func notifyUnrecoveredCleanup() {
+	actions, err := preflight.LastCleanupActions()
+	if err != nil {
+		logging.Warnf("could not read previous cleanup state: %v", err)
+		return
+	}
+	if len(actions) == 0 {
+		return
+	}
+	logging.Infof("undoing %d change(s) made by the last 'crc cleanup'", len(actions))
+	if err := preflight.ClearCleanupState(); err != nil {
+		logging.Warnf("could not clear cleanup state: %v", err)
+	}
+}
+
+// newSetupReporter returns the Reporter matching --output, and a flush
+// function to call once every check has run (nil if nothing needs it).
+func newSetupReporter(format string) (preflight.Reporter, func() error) {
+	switch format {
+	case "json":
+		r := preflight.NewJSONReporter(os.Stdout)
+		return r, r.Flush
+	case "ndjson":
+		return preflight.NewNDJSONReporter(os.Stdout), nil
+	default:
+		return textReporter{}, nil
+	}
+}
+
+// textReporter is the --output text default: it logs each check the
+// way `crc setup` always has, rather than emitting machine-readable
+// records.
+type textReporter struct{}
+
+func (textReporter) Report(result preflight.CheckResult) {
+	switch result.Status {
+	case preflight.StatusPass:
+		logging.Debugf("%s: OK", result.Description)
+	case preflight.StatusFixed:
+		logging.Infof("%s: fixed", result.Description)
+	case preflight.StatusSkipped:
+		logging.Debugf("%s: skipped", result.Description)
+	case preflight.StatusFail:
+		logging.Errorf("%s: %s", result.Description, result.Error)
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/machine/libmachine/host"
+	"github.com/spf13/cobra"
+)
+
+const machineName = "crc"
+
+var (
+	startNetworkMode string
+	startBundlePath  string
+)
+
+func init() {
+	startCmd.Flags().StringVar(&startNetworkMode, "network-mode", "", `Networking mode to run the CRC virtual machine with: "" (the hypervisor default), "vsock" or "wsl"`)
+	startCmd.Flags().StringVar(&startBundlePath, "bundle", "", "Path to the CRC bundle to start")
+	rootCmd.AddCommand(startCmd)
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the CRC virtual machine",
+	Long:  `Create the CRC virtual machine and start it. Creation runs every time; an already existing machine is not detected first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStart()
+	},
+}
+
+func runStart() error {
+	networkMode, err := parseNetworkMode(startNetworkMode)
+	if err != nil {
+		return err
+	}
+
+	driver, err := machine.NewDriver(networkMode, machineName, startBundlePath)
+	if err != nil {
+		return err
+	}
+
+	h := &host.Host{
+		Driver:     driver,
+		DriverName: driver.DriverName(),
+		Name:       machineName,
+	}
+
+	if err := h.Driver.PreCreateCheck(); err != nil {
+		return fmt.Errorf("preflight check for the %s driver failed: %w", h.DriverName, err)
+	}
+	if err := h.Driver.Create(); err != nil {
+		return fmt.Errorf("failed to create the CRC virtual machine: %w", err)
+	}
+	if err := h.Driver.Start(); err != nil {
+		return fmt.Errorf("failed to start the CRC virtual machine: %w", err)
+	}
+
+	url, err := h.Driver.GetURL()
+	if err != nil {
+		return err
+	}
+	fmt.Println("CRC virtual machine is running at", url)
+	return nil
+}
+
+// parseNetworkMode maps the --network-mode flag value to a
+// network.Mode, rejecting anything getPreflightChecksForDistro and
+// machine.NewDriver wouldn't otherwise recognize.
+func parseNetworkMode(value string) (network.Mode, error) {
+	switch network.Mode(value) {
+	case network.DefaultMode, network.VSockMode, network.WSLMode:
+		return network.Mode(value), nil
+	default:
+		return "", fmt.Errorf("unknown --network-mode %q", value)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/machine/libmachine/drivers"
+)
+
+// NewDriver returns the libmachine driver for machineName appropriate
+// to networkMode. WSLMode only makes sense on a Windows host, since it
+// runs the VM as a WSL2 distribution; see pkg/crc/machine/wsl.
+func NewDriver(networkMode network.Mode, machineName, imageSourcePath string) (drivers.Driver, error) {
+	switch networkMode {
+	case network.WSLMode:
+		return nil, fmt.Errorf("WSL is only supported on Windows hosts")
+	default:
+		return nil, fmt.Errorf("network mode %q has no machine driver in this build", networkMode)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build windows
+// +build windows
+
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/machine/wsl"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/machine/libmachine/drivers"
+)
+
+// NewDriver returns the libmachine driver for machineName appropriate
+// to networkMode, mirroring how preflight.getPreflightChecksForDistro
+// picks its checks for the same mode.
+func NewDriver(networkMode network.Mode, machineName, imageSourcePath string) (drivers.Driver, error) {
+	switch networkMode {
+	case network.WSLMode:
+		driver := wsl.NewDriver(machineName)
+		driver.ImageSourcePath = imageSourcePath
+		return driver, nil
+	default:
+		return nil, fmt.Errorf("network mode %q has no machine driver in this build", networkMode)
+	}
+}
@@ -0,0 +1,167 @@
+// Package wsl implements a libmachine driver that runs the CRC virtual
+// machine as a WSL2 distribution instead of a libvirt or Hyper-V/vsock
+// guest. It shells out to wsl.exe for every lifecycle operation and
+// exposes the OpenShift API through a port forwarded to localhost rather
+// than through vsock.
+package wsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/code-ready/machine/libmachine/drivers"
+	"github.com/code-ready/machine/libmachine/log"
+	"github.com/code-ready/machine/libmachine/state"
+)
+
+const (
+	// DistroName is the name under which the CRC rootfs is imported as a
+	// WSL distribution.
+	DistroName = "crc"
+
+	driverName = "wsl"
+
+	defaultAPIPort = 6443
+)
+
+// Driver drives a WSL2-backed CRC instance. It satisfies
+// drivers.Driver by shelling out to wsl.exe (--import, -d, --terminate,
+// --unregister, --list) instead of talking to a hypervisor API.
+type Driver struct {
+	*drivers.BaseDriver
+
+	// ImageSourcePath is the path to the CRC rootfs tarball that gets
+	// imported as the "crc" WSL distribution on Create.
+	ImageSourcePath string
+
+	// APIPort is the localhost port forwarded to the OpenShift API
+	// server running inside the WSL distribution.
+	APIPort int
+}
+
+// NewDriver creates a Driver for the named machine, ready to be passed
+// to host.Host.
+func NewDriver(machineName string) *Driver {
+	return &Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: machineName,
+		},
+		APIPort: defaultAPIPort,
+	}
+}
+
+func (d *Driver) DriverName() string {
+	return driverName
+}
+
+func (d *Driver) PreCreateCheck() error {
+	if _, err := exec.LookPath("wsl.exe"); err != nil {
+		return fmt.Errorf("wsl.exe not found in PATH: %w", err)
+	}
+	return nil
+}
+
+// Create imports the CRC rootfs as a WSL distribution. Forwarding the
+// OpenShift API port to localhost requires an elevated netsh call this
+// driver cannot make on its own, so that part of setup is handled by the
+// check-wsl-port-forwarding preflight check instead (see
+// preflight_windows_wsl.go).
+func (d *Driver) Create() error {
+	return d.importDistro()
+}
+
+func (d *Driver) importDistro() error {
+	log.Infof("Importing %s as WSL distribution %q", d.ImageSourcePath, DistroName)
+	installDir := d.ResolveStorePath(DistroName)
+	_, _, err := runWSL("--import", DistroName, installDir, d.ImageSourcePath, "--version", "2")
+	return err
+}
+
+func (d *Driver) Start() error {
+	log.Infof("Starting WSL distribution %q", DistroName)
+	_, _, err := runWSL("-d", DistroName, "--", "true")
+	return err
+}
+
+func (d *Driver) Stop() error {
+	log.Infof("Terminating WSL distribution %q", DistroName)
+	_, _, err := runWSL("--terminate", DistroName)
+	return err
+}
+
+func (d *Driver) Kill() error {
+	return d.Stop()
+}
+
+func (d *Driver) Restart() error {
+	if err := d.Stop(); err != nil {
+		return err
+	}
+	return d.Start()
+}
+
+func (d *Driver) Remove() error {
+	_, _, err := runWSL("--unregister", DistroName)
+	return err
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	stdout, _, err := runWSL("--list", "--running", "--quiet")
+	if err != nil {
+		return state.Error, err
+	}
+	for _, line := range strings.Split(decodeWSLOutput(stdout), "\n") {
+		if strings.TrimSpace(line) == DistroName {
+			return state.Running, nil
+		}
+	}
+	return state.Stopped, nil
+}
+
+func (d *Driver) GetIP() (string, error) {
+	return "127.0.0.1", nil
+}
+
+func (d *Driver) GetURL() (string, error) {
+	return fmt.Sprintf("https://127.0.0.1:%d", d.APIPort), nil
+}
+
+func (d *Driver) UpdateConfigRaw(rawConfig []byte) error {
+	var newDriver Driver
+	if err := json.Unmarshal(rawConfig, &newDriver); err != nil {
+		return fmt.Errorf("error unmarshalling new driver config: %w", err)
+	}
+	*d = newDriver
+	return nil
+}
+
+func (d *Driver) GetSSHPort() (int, error) {
+	return 0, drivers.ErrNotImplemented
+}
+
+// decodeWSLOutput decodes the UTF-16LE text wsl.exe writes to stdout
+// when it isn't attached to a console (as is the case when exec.Command
+// captures it), and strips the leading byte-order mark wsl.exe emits.
+func decodeWSLOutput(raw string) string {
+	b := []byte(raw)
+	u16 := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u16 = append(u16, uint16(b[i])|uint16(b[i+1])<<8)
+	}
+	return strings.TrimPrefix(string(utf16.Decode(u16)), "\uFEFF")
+}
+
+func runWSL(args ...string) (string, string, error) {
+	cmd := exec.Command("wsl.exe", args...)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		err = fmt.Errorf("wsl.exe %s: %w: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String(), stderr.String(), err
+}
@@ -0,0 +1,41 @@
+package wsl
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16leBytes(s string, bom bool) []byte {
+	runes := []rune(s)
+	if bom {
+		runes = append([]rune{'\uFEFF'}, runes...)
+	}
+	u16 := utf16.Encode(runes)
+	b := make([]byte, 0, len(u16)*2)
+	for _, u := range u16 {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestDecodeWSLOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		bom  bool
+		want string
+	}{
+		{"with BOM", "crc\n", true, "crc\n"},
+		{"without BOM", "crc\n", false, "crc\n"},
+		{"multiple lines", "docker-desktop\r\ncrc\r\n", true, "docker-desktop\r\ncrc\r\n"},
+		{"empty", "", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := string(utf16leBytes(tt.in, tt.bom))
+			if got := decodeWSLOutput(raw); got != tt.want {
+				t.Errorf("decodeWSLOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package network describes the networking modes crc can run the CRC
+// virtual machine with, so that both the preflight checks and the
+// machine driver selection agree on which mode is in effect.
+package network
+
+// Mode selects how the OpenShift API and other CRC VM services are
+// reached from the host.
+type Mode string
+
+const (
+	// DefaultMode uses the hypervisor's own networking (libvirt's
+	// 'crc' network on Linux, a Hyper-V switch on Windows).
+	DefaultMode Mode = ""
+
+	// VSockMode reaches the VM over a vsock device instead of a
+	// hypervisor network, used when the host firewall or VPN setup
+	// makes the default network unreachable.
+	VSockMode Mode = "vsock"
+
+	// WSLMode runs the VM as a WSL2 distribution instead of through a
+	// hypervisor at all; see pkg/crc/machine/wsl.
+	WSLMode Mode = "wsl"
+)
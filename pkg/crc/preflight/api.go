@@ -0,0 +1,17 @@
+package preflight
+
+// AllPreflightChecks returns every preflight check `crc setup` and
+// `crc cleanup` run against the current host, including the vsock
+// checks and the distribution-specific libvirt checks. It is the entry
+// point the CLI layer uses to reach the otherwise unexported check
+// registry built up by getAllPreflightChecks.
+func AllPreflightChecks() []Check {
+	return getAllPreflightChecks()
+}
+
+// RunPreflightChecks runs every preflight check, reporting each result
+// to reporter as it completes; `crc setup` uses it for every --output
+// format.
+func RunPreflightChecks(reporter Reporter) []CheckResult {
+	return RunChecks(AllPreflightChecks(), reporter)
+}
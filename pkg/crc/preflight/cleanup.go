@@ -0,0 +1,159 @@
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// cleanupStateFile records what a `crc cleanup` run actually did, so a
+// later `crc setup` can offer to put things back the way they were.
+const cleanupStateFile = "cleanup-state.json"
+
+// Action describes a single step a cleanup check would take, without
+// actually taking it. Planner uses these to build a preview of
+// `crc cleanup` before anything destructive runs.
+type Action struct {
+	Description string `json:"description"`
+	Command     string `json:"command"`
+	Reversible  bool   `json:"reversible"`
+}
+
+// ExecutedAction is an Action that was actually run, recorded to the
+// cleanup state file so a subsequent `crc setup` can undo it.
+type ExecutedAction struct {
+	Action
+	ExecutedAt time.Time `json:"executedAt"`
+}
+
+// cleanupState is the on-disk shape of cleanupStateFile.
+type cleanupState struct {
+	Actions []ExecutedAction `json:"actions"`
+}
+
+// Planner walks a set of checks and produces a dry-run plan of what
+// their cleanup steps would do, without running any of them.
+type Planner struct {
+	checks []Check
+}
+
+// NewPlanner creates a Planner over checks, typically the result of
+// getAllPreflightChecks().
+func NewPlanner(checks []Check) *Planner {
+	return &Planner{checks: checks}
+}
+
+// PlannedCheck is one check's contribution to a cleanup Plan.
+type PlannedCheck struct {
+	ConfigKeySuffix string   `json:"configKeySuffix"`
+	Description     string   `json:"description"`
+	Actions         []Action `json:"actions"`
+}
+
+// Plan dry-runs every check that supports it and returns what a real
+// `crc cleanup` would do, in the order the checks would run.
+func (p *Planner) Plan() []PlannedCheck {
+	var plan []PlannedCheck
+	for _, c := range p.checks {
+		if c.cleanupDryRun == nil {
+			continue
+		}
+		actions := c.cleanupDryRun()
+		if len(actions) == 0 {
+			continue
+		}
+		plan = append(plan, PlannedCheck{
+			ConfigKeySuffix: c.configKeySuffix,
+			Description:     c.cleanupDescription,
+			Actions:         actions,
+		})
+	}
+	return plan
+}
+
+// Execute runs the real cleanup function of every check that has one,
+// collecting the dry-run actions of each check whose cleanup succeeded
+// into a fresh cleanup state. That state is written to disk once every
+// check has run, or as soon as one fails, so it still covers the checks
+// that completed before the failure. It replaces the state from any
+// previous `crc cleanup` run: LastCleanupActions only reflects this run.
+func (p *Planner) Execute() error {
+	var state cleanupState
+	for _, c := range p.checks {
+		if c.cleanup == nil {
+			continue
+		}
+		var actions []Action
+		if c.cleanupDryRun != nil {
+			actions = c.cleanupDryRun()
+		}
+		if err := c.cleanup(); err != nil {
+			if saveErr := saveCleanupState(state); saveErr != nil {
+				logging.Warnf("failed to save cleanup state: %v", saveErr)
+			}
+			return fmt.Errorf("%s: %w", c.cleanupDescription, err)
+		}
+		now := time.Now()
+		for _, action := range actions {
+			state.Actions = append(state.Actions, ExecutedAction{Action: action, ExecutedAt: now})
+		}
+	}
+	return saveCleanupState(state)
+}
+
+// cleanupStateDir is where cleanupStatePath looks for cleanupStateFile;
+// a var rather than using constants.CrcBaseDir directly so tests can
+// point it at a temp directory.
+var cleanupStateDir = constants.CrcBaseDir
+
+func cleanupStatePath() string {
+	return filepath.Join(cleanupStateDir, cleanupStateFile)
+}
+
+func saveCleanupState(state cleanupState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cleanupStatePath(), data, 0600)
+}
+
+// LastCleanupActions returns the reversible actions recorded by the
+// most recent `crc cleanup` run. `crc setup` reports how many there are;
+// it does not prompt the user before its checks recreate them.
+func LastCleanupActions() ([]ExecutedAction, error) {
+	data, err := ioutil.ReadFile(cleanupStatePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state cleanupState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	var reversible []ExecutedAction
+	for _, action := range state.Actions {
+		if action.Reversible {
+			reversible = append(reversible, action)
+		}
+	}
+	return reversible, nil
+}
+
+// ClearCleanupState removes the recorded cleanup state. `crc setup` calls
+// it after reporting the recorded actions, before any of its checks run.
+func ClearCleanupState() error {
+	err := os.Remove(cleanupStatePath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package preflight
+
+// removeLibvirtCrcNetworkDryRun previews what removeLibvirtCrcNetwork
+// would do: undefining and, if active, destroying the libvirt 'crc'
+// network. It is reversible because fixLibvirtCrcNetworkAvailable can
+// recreate the network from the same XML definition on a later
+// `crc setup`.
+func removeLibvirtCrcNetworkDryRun() []Action {
+	return []Action{
+		{
+			Description: "Destroy the libvirt 'crc' network if it is active",
+			Command:     "virsh net-destroy crc",
+			Reversible:  true,
+		},
+		{
+			Description: "Undefine the libvirt 'crc' network",
+			Command:     "virsh net-undefine crc",
+			Reversible:  true,
+		},
+	}
+}
+
+// removeCrcVMDryRun previews what removeCrcVM would do. Removing the VM
+// is not reversible: the disk image and its contents are gone for good,
+// and a later `crc setup`/`crc start` simply creates a fresh one.
+func removeCrcVMDryRun() []Action {
+	return []Action{
+		{
+			Description: "Stop the 'crc' libvirt domain if it is running",
+			Command:     "virsh destroy crc",
+			Reversible:  false,
+		},
+		{
+			Description: "Undefine the 'crc' libvirt domain and remove its disk image",
+			Command:     "virsh undefine crc --remove-all-storage",
+			Reversible:  false,
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package preflight
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlannerPlanSkipsChecksWithoutDryRun(t *testing.T) {
+	checks := []Check{
+		{
+			configKeySuffix:    "check-a",
+			cleanupDescription: "Remove A",
+			cleanupDryRun: func() []Action {
+				return []Action{{Description: "remove a", Reversible: true}}
+			},
+		},
+		{
+			configKeySuffix:    "check-b",
+			cleanupDescription: "Remove B",
+			cleanup:            func() error { return nil },
+		},
+	}
+
+	plan := NewPlanner(checks).Plan()
+
+	if len(plan) != 1 {
+		t.Fatalf("len(plan) = %d, want 1", len(plan))
+	}
+	if plan[0].ConfigKeySuffix != "check-a" {
+		t.Errorf("plan[0].ConfigKeySuffix = %q, want %q", plan[0].ConfigKeySuffix, "check-a")
+	}
+}
+
+func TestPlannerExecuteWritesFreshState(t *testing.T) {
+	dir := t.TempDir()
+	old := cleanupStateDir
+	cleanupStateDir = dir
+	defer func() { cleanupStateDir = old }()
+
+	// A previous run's state file should be fully replaced, not
+	// appended to.
+	stale := cleanupState{Actions: []ExecutedAction{{Action: Action{Description: "stale", Reversible: true}}}}
+	data, err := json.Marshal(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, cleanupStateFile), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ran := false
+	checks := []Check{
+		{
+			configKeySuffix:    "check-a",
+			cleanupDescription: "Remove A",
+			cleanupDryRun: func() []Action {
+				return []Action{{Description: "remove a", Reversible: true}}
+			},
+			cleanup: func() error { ran = true; return nil },
+		},
+	}
+
+	if err := NewPlanner(checks).Execute(); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if !ran {
+		t.Error("cleanup was not run")
+	}
+
+	actions, err := LastCleanupActions()
+	if err != nil {
+		t.Fatalf("LastCleanupActions() = %v", err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("len(actions) = %d, want 1: %+v", len(actions), actions)
+	}
+	if actions[0].Description != "remove a" {
+		t.Errorf("actions[0].Description = %q, want %q", actions[0].Description, "remove a")
+	}
+}
+
+func TestClearCleanupState(t *testing.T) {
+	dir := t.TempDir()
+	old := cleanupStateDir
+	cleanupStateDir = dir
+	defer func() { cleanupStateDir = old }()
+
+	checks := []Check{
+		{
+			configKeySuffix:    "check-a",
+			cleanupDescription: "Remove A",
+			cleanupDryRun: func() []Action {
+				return []Action{{Description: "remove a", Reversible: true}}
+			},
+			cleanup: func() error { return nil },
+		},
+	}
+	if err := NewPlanner(checks).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ClearCleanupState(); err != nil {
+		t.Fatalf("ClearCleanupState() = %v", err)
+	}
+
+	actions, err := LastCleanupActions()
+	if err != nil {
+		t.Fatalf("LastCleanupActions() = %v", err)
+	}
+	if len(actions) != 0 {
+		t.Errorf("len(actions) = %d, want 0 after Clear", len(actions))
+	}
+}
@@ -0,0 +1,244 @@
+package preflight
+
+import (
+	"fmt"
+	"os/user"
+	"strings"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/code-ready/crc/pkg/os/linux"
+)
+
+// DistroHandler encapsulates everything the libvirt preflight checks
+// need to know about a given Linux distribution family: how to detect
+// it, how to install libvirt on it, and what its libvirt group is
+// called. Packagers supporting a distribution that isn't covered by the
+// handlers in this file can add their own with Register.
+type DistroHandler interface {
+	// Matches reports whether this handler knows how to deal with the
+	// distribution described by release.
+	Matches(release linux.OsRelease) bool
+
+	// LibvirtChecks returns the additional preflight checks that should
+	// run on this distribution once libvirt itself is known to be
+	// present (package checks, group membership, etc. still go through
+	// the checks returned by commonChecks).
+	LibvirtChecks() []Check
+
+	// PackageInstallCmd returns the command, as an argv slice suitable
+	// for crcos.RunWithPrivilege, used to install pkg on this
+	// distribution.
+	PackageInstallCmd(pkg string) []string
+
+	// LibvirtGroupName returns the name of the group that grants access
+	// to the libvirt socket on this distribution.
+	LibvirtGroupName() string
+}
+
+var distroHandlers []DistroHandler
+
+// Register adds a DistroHandler to the registry consulted by
+// selectDistroHandler. Handlers are tried in registration order, so more
+// specific handlers should be registered before generic fallbacks.
+func Register(handler DistroHandler) {
+	distroHandlers = append(distroHandlers, handler)
+}
+
+func init() {
+	Register(rhelFamilyHandler{})
+	Register(debianFamilyHandler{})
+	Register(archHandler{})
+	Register(openSUSEHandler{})
+}
+
+// selectDistroHandler returns the first registered handler that matches
+// release, or genericHandler when none does, so this never returns nil.
+func selectDistroHandler(release linux.OsRelease) DistroHandler {
+	for _, handler := range distroHandlers {
+		if handler.Matches(release) {
+			return handler
+		}
+	}
+	return genericHandler{}
+}
+
+func osRelease() (linux.OsRelease, error) {
+	return linux.GetOsRelease()
+}
+
+// rhelFamilyHandler covers RHEL, CentOS and Fedora, which all use dnf/yum
+// and the "libvirt" group.
+type rhelFamilyHandler struct{}
+
+func (rhelFamilyHandler) Matches(release linux.OsRelease) bool {
+	switch release.ID {
+	case linux.RHEL, linux.CentOS, linux.Fedora:
+		return true
+	default:
+		return false
+	}
+}
+
+func (rhelFamilyHandler) LibvirtChecks() []Check {
+	return redhatPreflightChecks[:]
+}
+
+func (rhelFamilyHandler) PackageInstallCmd(pkg string) []string {
+	return []string{"dnf", "install", "-y", pkg}
+}
+
+func (rhelFamilyHandler) LibvirtGroupName() string {
+	return "libvirt"
+}
+
+// debianFamilyHandler covers Debian and its derivatives (Ubuntu, Mint,
+// ...), which use apt-get and the "libvirtd" group rather than
+// "libvirt".
+type debianFamilyHandler struct{}
+
+func (debianFamilyHandler) Matches(release linux.OsRelease) bool {
+	if release.ID == linux.Ubuntu || release.ID == linux.Debian {
+		return true
+	}
+	for _, like := range release.IDLike {
+		if like == linux.Debian {
+			return true
+		}
+	}
+	return false
+}
+
+func (debianFamilyHandler) LibvirtChecks() []Check {
+	return nil
+}
+
+func (debianFamilyHandler) PackageInstallCmd(pkg string) []string {
+	return []string{"apt-get", "install", "-y", pkg}
+}
+
+func (debianFamilyHandler) LibvirtGroupName() string {
+	return "libvirtd"
+}
+
+// archHandler covers Arch Linux and Manjaro, which use pacman.
+type archHandler struct{}
+
+func (archHandler) Matches(release linux.OsRelease) bool {
+	if release.ID == linux.Arch {
+		return true
+	}
+	for _, like := range release.IDLike {
+		if like == linux.Arch {
+			return true
+		}
+	}
+	return false
+}
+
+func (archHandler) LibvirtChecks() []Check {
+	return nil
+}
+
+func (archHandler) PackageInstallCmd(pkg string) []string {
+	return []string{"pacman", "-S", "--noconfirm", pkg}
+}
+
+func (archHandler) LibvirtGroupName() string {
+	return "libvirt"
+}
+
+// openSUSEHandler covers openSUSE Leap and Tumbleweed, which use zypper.
+type openSUSEHandler struct{}
+
+func (openSUSEHandler) Matches(release linux.OsRelease) bool {
+	return release.ID == linux.OpenSUSE
+}
+
+func (openSUSEHandler) LibvirtChecks() []Check {
+	return nil
+}
+
+func (openSUSEHandler) PackageInstallCmd(pkg string) []string {
+	return []string{"zypper", "install", "-y", pkg}
+}
+
+func (openSUSEHandler) LibvirtGroupName() string {
+	return "libvirt"
+}
+
+// genericHandler is the fallback used for distributions with no
+// dedicated handler. It matches anything, assumes the RHEL-style
+// "libvirt" group, and has no package manager it can drive on its own,
+// so PackageInstallCmd just fails loudly instead of guessing.
+type genericHandler struct{}
+
+func (genericHandler) Matches(linux.OsRelease) bool {
+	return true
+}
+
+func (genericHandler) LibvirtChecks() []Check {
+	return redhatPreflightChecks[:]
+}
+
+func (genericHandler) PackageInstallCmd(pkg string) []string {
+	return nil
+}
+
+func (genericHandler) LibvirtGroupName() string {
+	return "libvirt"
+}
+
+func checkLibvirtInstalled() error {
+	if _, _, err := crcos.RunWithDefaultLocale("libvirtd", "--version"); err != nil {
+		return newCheckError(CategoryNotInstalled, "libvirt is not installed: %v", err)
+	}
+	return nil
+}
+
+func fixLibvirtInstalled() error {
+	release, err := osRelease()
+	if err != nil {
+		return err
+	}
+	handler := selectDistroHandler(release)
+	installCmd := handler.PackageInstallCmd("libvirt")
+	if len(installCmd) == 0 {
+		return newCheckError(CategoryUnsupported, "don't know how to install libvirt on %s: please install libvirt-daemon-system and libvirt-clients manually", release.ID)
+	}
+	if _, _, err := crcos.RunWithPrivilege("install libvirt", installCmd[0], installCmd[1:]...); err != nil {
+		return err
+	}
+	return nil
+}
+
+func checkUserPartOfLibvirtGroup() error {
+	release, err := osRelease()
+	if err != nil {
+		return err
+	}
+	groupName := selectDistroHandler(release).LibvirtGroupName()
+	out, _, err := crcos.RunWithDefaultLocale("groups")
+	if err != nil {
+		return err
+	}
+	for _, group := range strings.Fields(out) {
+		if group == groupName {
+			return nil
+		}
+	}
+	return newCheckError(CategoryNotConfigured, "current user is not part of the %q group", groupName)
+}
+
+func fixUserPartOfLibvirtGroup() error {
+	release, err := osRelease()
+	if err != nil {
+		return err
+	}
+	groupName := selectDistroHandler(release).LibvirtGroupName()
+	currentUser, err := user.Current()
+	if err != nil {
+		return err
+	}
+	_, _, err = crcos.RunWithPrivilege(fmt.Sprintf("add user to %s group", groupName), "usermod", "-a", "-G", groupName, currentUser.Username)
+	return err
+}
@@ -0,0 +1,61 @@
+package preflight
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCategory classifies why a preflight check or fix failed, so that
+// machine consumers (IDE plugins, CI) can react to the failure kind
+// instead of pattern-matching an error string.
+type ErrorCategory string
+
+const (
+	// CategoryNotInstalled means a required binary or package is missing.
+	CategoryNotInstalled ErrorCategory = "not-installed"
+	// CategoryNotConfigured means the dependency is installed but not
+	// set up correctly (wrong group, wrong permissions, disabled
+	// service, ...).
+	CategoryNotConfigured ErrorCategory = "not-configured"
+	// CategoryNotRunning means a required service is installed but not
+	// currently running.
+	CategoryNotRunning ErrorCategory = "not-running"
+	// CategoryUnsupported means the check cannot succeed on this
+	// platform/distribution at all.
+	CategoryUnsupported ErrorCategory = "unsupported"
+	// CategoryPermission means the check or fix failed because of
+	// insufficient privileges.
+	CategoryPermission ErrorCategory = "permission"
+)
+
+// CheckError is the error type preflight checks and fixes should return
+// so failures carry a stable, machine-readable category alongside the
+// human-readable message.
+type CheckError struct {
+	Category ErrorCategory
+	Err      error
+}
+
+func (e *CheckError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
+// newCheckError returns a CheckError of category whose error is built
+// from format and args the same way fmt.Errorf would.
+func newCheckError(category ErrorCategory, format string, args ...interface{}) *CheckError {
+	return &CheckError{Category: category, Err: fmt.Errorf(format, args...)}
+}
+
+// categoryOf extracts the ErrorCategory from err if it (or something it
+// wraps) is a *CheckError, otherwise it returns an empty category.
+func categoryOf(err error) ErrorCategory {
+	var checkErr *CheckError
+	if errors.As(err, &checkErr) {
+		return checkErr.Category
+	}
+	return ""
+}
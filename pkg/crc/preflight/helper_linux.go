@@ -0,0 +1,32 @@
+package preflight
+
+import (
+	"fmt"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// crc-setup-helper is a multi-call binary (see cmd/crc-setup-helper):
+// which single action it performs is fixed by the path it was invoked
+// as, not by an argument, because pkexec decides which polkit action to
+// authorize from the executable path alone, so two distinct actions
+// can't share one path without becoming ambiguous. The packaging
+// installs it at setupHelperPath for the vsock fix and symlinks
+// setupHelperNetworkPath to the same binary for the libvirt network
+// fix (see packaging/linux/org.crc.setup.policy).
+const (
+	setupHelperPath        = "/usr/libexec/crc-setup-helper"
+	setupHelperNetworkPath = "/usr/libexec/crc-setup-helper-network"
+)
+
+// runSetupHelper runs helperPath under pkexec with args, authenticating
+// once for whichever single action that path is registered for.
+// description is only used for the error message wrapping pkexec's own
+// failure.
+func runSetupHelper(description, helperPath string, args ...string) error {
+	helperArgs := append([]string{helperPath}, args...)
+	if _, _, err := crcos.RunWithDefaultLocale("pkexec", helperArgs...); err != nil {
+		return fmt.Errorf("%s: %w", description, err)
+	}
+	return nil
+}
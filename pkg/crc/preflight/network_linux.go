@@ -0,0 +1,15 @@
+package preflight
+
+// crcNetworkXMLPath is the libvirt network definition packaged with crc
+// (see packaging/linux/crc-network.xml) describing the 'crc' NAT
+// network the libvirt driver attaches the VM to.
+const crcNetworkXMLPath = "/usr/share/crc/crc-network.xml"
+
+// fixLibvirtCrcNetworkAvailable defines and autostarts the 'crc'
+// libvirt network from crcNetworkXMLPath, going through
+// crc-setup-helper so the 'virsh net-define'/'net-autostart' calls run
+// with a single polkit authentication instead of prompting crc's caller
+// to run libvirt commands as root directly.
+func fixLibvirtCrcNetworkAvailable() error {
+	return runSetupHelper("setting up the 'crc' libvirt network", setupHelperNetworkPath, crcNetworkXMLPath)
+}
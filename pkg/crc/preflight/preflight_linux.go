@@ -11,7 +11,6 @@ import (
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
 	crcos "github.com/code-ready/crc/pkg/os"
-	"github.com/code-ready/crc/pkg/os/linux"
 )
 
 var libvirtPreflightChecks = [...]Check{
@@ -80,6 +79,7 @@ var libvirtPreflightChecks = [...]Check{
 		fix:                fixLibvirtCrcNetworkAvailable,
 		cleanupDescription: "Removing 'crc' network from libvirt",
 		cleanup:            removeLibvirtCrcNetwork,
+		cleanupDryRun:      removeLibvirtCrcNetworkDryRun,
 	},
 	{
 		configKeySuffix:  "check-crc-network-active",
@@ -91,6 +91,7 @@ var libvirtPreflightChecks = [...]Check{
 	{
 		cleanupDescription: "Removing the crc VM if exists",
 		cleanup:            removeCrcVM,
+		cleanupDryRun:      removeCrcVMDryRun,
 		flags:              CleanUpOnly,
 	},
 }
@@ -113,11 +114,11 @@ func checkVsock() error {
 		return err
 	}
 	if !strings.Contains(string(getcap), "cap_net_bind_service+eip") {
-		return fmt.Errorf("capabilities are not correct for %s", executable)
+		return newCheckError(CategoryNotConfigured, "capabilities are not correct for %s", executable)
 	}
 	info, err := os.Stat("/dev/vsock")
 	if err != nil {
-		return err
+		return newCheckError(CategoryNotConfigured, "/dev/vsock does not exist: %v", err)
 	}
 	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
 		group, err := user.LookupGroupId(fmt.Sprint(stat.Gid))
@@ -125,13 +126,13 @@ func checkVsock() error {
 			return err
 		}
 		if group.Name != "libvirt" {
-			return errors.New("/dev/vsock is not is the right group")
+			return newCheckError(CategoryNotConfigured, "/dev/vsock is not is the right group")
 		}
 	} else {
 		return errors.New("cannot cast info")
 	}
 	if info.Mode()&0060 == 0 {
-		return errors.New("/dev/vsock doesn't have the right permissions")
+		return newCheckError(CategoryNotConfigured, "/dev/vsock doesn't have the right permissions")
 	}
 	return nil
 }
@@ -141,53 +142,32 @@ func fixVsock() error {
 	if err != nil {
 		return err
 	}
-	_, _, err = crcos.RunWithPrivilege("setcap cap_net_bind_service=+eip", "setcap", "cap_net_bind_service=+eip", executable)
-	if err != nil {
-		return err
-	}
-	_, _, err = crcos.RunWithPrivilege("modprobe vhost_vsock", "modprobe", "vhost_vsock")
-	if err != nil {
-		return err
-	}
-	_, _, err = crcos.RunWithPrivilege("chown /dev/vsock", "chown", "root:libvirt", "/dev/vsock")
-	if err != nil {
-		return err
-	}
-	_, _, err = crcos.RunWithPrivilege("chmod /dev/vsock", "chmod", "g+rw", "/dev/vsock")
-	if err != nil {
-		return err
-	}
-	return nil
+	return runSetupHelper("configuring vsock", setupHelperPath, executable)
 }
 
 func getAllPreflightChecks() []Check {
-	checks := getPreflightChecksForDistro(distro(), network.DefaultMode)
+	checks := getPreflightChecksForDistro(network.DefaultMode)
 	checks = append(checks, vsockPreflightChecks)
 	return checks
 }
 
 func getPreflightChecks(_ bool, networkMode network.Mode) []Check {
-	return getPreflightChecksForDistro(distro(), networkMode)
+	return getPreflightChecksForDistro(networkMode)
 }
 
-func getPreflightChecksForDistro(distro linux.OsType, networkMode network.Mode) []Check {
+func getPreflightChecksForDistro(networkMode network.Mode) []Check {
 	checks := commonChecks()
 
 	if networkMode == network.VSockMode {
 		checks = append(checks, vsockPreflightChecks)
 	}
 
-	switch distro {
-	case linux.Ubuntu:
-	case linux.RHEL, linux.CentOS, linux.Fedora:
-		if networkMode == network.DefaultMode {
-			checks = append(checks, redhatPreflightChecks[:]...)
-		}
-	default:
-		logging.Warnf("distribution-specific preflight checks are not implemented for %s", distro)
-		if networkMode == network.DefaultMode {
-			checks = append(checks, redhatPreflightChecks[:]...)
+	if networkMode == network.DefaultMode {
+		release, err := osRelease()
+		if err != nil {
+			logging.Warnf("cannot get distribution release info: %v", err)
 		}
+		checks = append(checks, selectDistroHandler(release).LibvirtChecks()...)
 	}
 
 	return checks
@@ -200,12 +180,3 @@ func commonChecks() []Check {
 	checks = append(checks, libvirtPreflightChecks[:]...)
 	return checks
 }
-
-func distro() linux.OsType {
-	distro, err := linux.GetOsRelease()
-	if err != nil {
-		logging.Warnf("cannot get distribution name: %v", err)
-		return "unknown"
-	}
-	return distro.ID
-}
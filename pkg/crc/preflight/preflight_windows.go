@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package preflight
+
+import (
+	"github.com/code-ready/crc/pkg/crc/network"
+)
+
+func getAllPreflightChecks() []Check {
+	return getPreflightChecksForDistro(network.DefaultMode)
+}
+
+func getPreflightChecks(_ bool, networkMode network.Mode) []Check {
+	return getPreflightChecksForDistro(networkMode)
+}
+
+func getPreflightChecksForDistro(networkMode network.Mode) []Check {
+	checks := commonChecks()
+
+	switch networkMode {
+	case network.WSLMode:
+		checks = append(checks, wslPreflightChecks[:]...)
+	default:
+		checks = append(checks, hypervPreflightChecks[:]...)
+		checks = append(checks, vsockWindowsPreflightChecks[:]...)
+	}
+
+	return checks
+}
+
+func commonChecks() []Check {
+	var checks []Check
+	checks = append(checks, genericPreflightChecks[:]...)
+	return checks
+}
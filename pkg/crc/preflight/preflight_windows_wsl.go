@@ -0,0 +1,236 @@
+//go:build windows
+// +build windows
+
+package preflight
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// wslPreflightChecks are the checks used when network.WSLMode is
+// selected: they replace the Hyper-V/vsock checks entirely, since the
+// CRC VM runs as a WSL2 distribution and does not need a Hyper-V
+// switch, a vsock device, or the Hyper-V admin group.
+var wslPreflightChecks = [...]Check{
+	{
+		configKeySuffix:  "check-wsl-installed",
+		checkDescription: "Checking if the Windows Subsystem for Linux is installed and enabled",
+		check:            checkWSLInstalled,
+		fixDescription:   "Enabling the Windows Subsystem for Linux feature",
+		fix:              fixWSLInstalled,
+	},
+	{
+		configKeySuffix:  "check-wsl-kernel-version",
+		checkDescription: "Checking if a WSL2 kernel is installed",
+		check:            checkWSLKernelVersion,
+		fixDescription:   "Updating the WSL2 kernel",
+		fix:              fixWSLKernelVersion,
+	},
+	{
+		configKeySuffix:  "check-vmcompute-running",
+		checkDescription: "Checking if the Hyper-V Host Compute Service is running",
+		check:            checkVmcomputeServiceRunning,
+		fixDescription:   "Starting the Hyper-V Host Compute Service",
+		fix:              fixVmcomputeServiceRunning,
+	},
+	{
+		configKeySuffix:  "check-wsl-distro-imported",
+		checkDescription: "Checking if the CRC WSL distribution is imported",
+		check:            checkWSLDistroImported,
+		fixDescription:   "Importing the CRC WSL distribution",
+		fix:              fixWSLDistroImported,
+	},
+	{
+		configKeySuffix:    "check-wsl-port-forwarding",
+		checkDescription:   "Checking if the OpenShift API port is forwarded to localhost",
+		check:              checkWSLPortForwarding,
+		fixDescription:     "Forwarding the OpenShift API port to localhost",
+		fix:                fixWSLPortForwarding,
+		cleanupDescription: "Removing the OpenShift API port forward",
+		cleanup:            removeWSLPortForwarding,
+		cleanupDryRun:      removeWSLPortForwardingDryRun,
+	},
+}
+
+const (
+	wslDistroName       = "crc"
+	wslForwardedAPIPort = 6443
+)
+
+// bundleRootfsPath returns the path to the rootfs tarball extracted from
+// the currently selected CRC bundle, mirroring how the libvirt and
+// Hyper-V drivers locate the qcow2/vhdx disk image they import.
+func bundleRootfsPath() (string, error) {
+	return constants.CrcBundleRootfsPath, nil
+}
+
+// wslDistroInstallDir returns the directory wsl.exe should unpack the
+// "crc" distribution into, under the CRC machine directory.
+func wslDistroInstallDir() (string, error) {
+	return filepath.Join(constants.MachineBaseDir, wslDistroName), nil
+}
+
+// wslDistroIPAddress returns the IP address the "crc" WSL distribution
+// is reachable at on its internal NAT interface.
+func wslDistroIPAddress() (string, error) {
+	out, _, err := crcos.RunWithDefaultLocale("wsl.exe", "-d", wslDistroName, "--", "hostname", "-I")
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the WSL distribution IP address: %w", err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("the %q WSL distribution reported no IP address", wslDistroName)
+	}
+	return fields[0], nil
+}
+
+func checkWSLInstalled() error {
+	out, _, err := crcos.RunWithDefaultLocale("powershell", "-NoProfile", "-Command",
+		"(Get-WindowsOptionalFeature -Online -FeatureName VirtualMachinePlatform).State")
+	if err != nil {
+		return fmt.Errorf("unable to query the VirtualMachinePlatform feature: %w", err)
+	}
+	if !strings.Contains(out, "Enabled") {
+		return fmt.Errorf("the Windows Subsystem for Linux is not enabled")
+	}
+	if _, err := exec.LookPath("wsl.exe"); err != nil {
+		return fmt.Errorf("wsl.exe was not found: %w", err)
+	}
+	return nil
+}
+
+func fixWSLInstalled() error {
+	_, _, err := crcos.RunWithPrivilege("enable WSL", "dism.exe", "/online", "/enable-feature",
+		"/featurename:Microsoft-Windows-Subsystem-Linux", "/all", "/norestart")
+	if err != nil {
+		return err
+	}
+	_, _, err = crcos.RunWithPrivilege("enable VirtualMachinePlatform", "dism.exe", "/online", "/enable-feature",
+		"/featurename:VirtualMachinePlatform", "/all", "/norestart")
+	return err
+}
+
+// checkWSLKernelVersion checks that `wsl.exe --version` runs and prints
+// something; it does not compare the reported kernel version against
+// any minimum.
+func checkWSLKernelVersion() error {
+	out, _, err := crcos.RunWithDefaultLocale("wsl.exe", "--version")
+	if err != nil {
+		return fmt.Errorf("unable to determine the WSL2 kernel version: %w", err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return fmt.Errorf("no WSL2 kernel is installed")
+	}
+	return nil
+}
+
+func fixWSLKernelVersion() error {
+	_, _, err := crcos.RunWithDefaultLocale("wsl.exe", "--update")
+	return err
+}
+
+func checkVmcomputeServiceRunning() error {
+	out, _, err := crcos.RunWithDefaultLocale("powershell", "-NoProfile", "-Command",
+		"(Get-Service vmcompute).Status")
+	if err != nil {
+		return fmt.Errorf("unable to query the vmcompute service: %w", err)
+	}
+	if !strings.Contains(out, "Running") {
+		return fmt.Errorf("the Hyper-V Host Compute Service is not running")
+	}
+	return nil
+}
+
+func fixVmcomputeServiceRunning() error {
+	_, _, err := crcos.RunWithPrivilege("start vmcompute", "net", "start", "vmcompute")
+	return err
+}
+
+func checkWSLDistroImported() error {
+	out, _, err := crcos.RunWithDefaultLocale("wsl.exe", "--list", "--quiet")
+	if err != nil {
+		return fmt.Errorf("unable to list WSL distributions: %w", err)
+	}
+	for _, line := range strings.Split(decodeWSLOutput(out), "\n") {
+		if strings.TrimSpace(line) == wslDistroName {
+			return nil
+		}
+	}
+	return fmt.Errorf("the %q WSL distribution is not imported", wslDistroName)
+}
+
+func fixWSLDistroImported() error {
+	rootfs, err := bundleRootfsPath()
+	if err != nil {
+		return err
+	}
+	installDir, err := wslDistroInstallDir()
+	if err != nil {
+		return err
+	}
+	_, _, err = crcos.RunWithDefaultLocale("wsl.exe", "--import", wslDistroName, installDir, rootfs, "--version", "2")
+	return err
+}
+
+// decodeWSLOutput decodes the UTF-16LE text wsl.exe writes to stdout
+// when it isn't attached to a console (as is the case here, since
+// crcos.RunWithDefaultLocale captures it via a pipe), and strips the
+// leading byte-order mark wsl.exe emits.
+func decodeWSLOutput(raw string) string {
+	b := []byte(raw)
+	u16 := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u16 = append(u16, uint16(b[i])|uint16(b[i+1])<<8)
+	}
+	return strings.TrimPrefix(string(utf16.Decode(u16)), "\uFEFF")
+}
+
+func checkWSLPortForwarding() error {
+	out, _, err := crcos.RunWithDefaultLocale("netsh", "interface", "portproxy", "show", "v4tov4")
+	if err != nil {
+		return fmt.Errorf("unable to list netsh port proxies: %w", err)
+	}
+	if !strings.Contains(out, strconv.Itoa(wslForwardedAPIPort)) {
+		return fmt.Errorf("port %d is not forwarded to the WSL distribution", wslForwardedAPIPort)
+	}
+	return nil
+}
+
+func fixWSLPortForwarding() error {
+	ip, err := wslDistroIPAddress()
+	if err != nil {
+		return err
+	}
+	_, _, err = crcos.RunWithPrivilege("forward OpenShift API port", "netsh", "interface", "portproxy", "add", "v4tov4",
+		fmt.Sprintf("listenport=%d", wslForwardedAPIPort), "listenaddress=127.0.0.1",
+		fmt.Sprintf("connectport=%d", wslForwardedAPIPort), fmt.Sprintf("connectaddress=%s", ip))
+	return err
+}
+
+func removeWSLPortForwarding() error {
+	_, _, err := crcos.RunWithPrivilege("remove OpenShift API port forward", "netsh", "interface", "portproxy", "delete", "v4tov4",
+		fmt.Sprintf("listenport=%d", wslForwardedAPIPort), "listenaddress=127.0.0.1")
+	return err
+}
+
+// removeWSLPortForwardingDryRun previews what removeWSLPortForwarding
+// would do: deleting the netsh port-forward. It is reversible because
+// fixWSLPortForwarding can re-add it on a later `crc setup`.
+func removeWSLPortForwardingDryRun() []Action {
+	return []Action{
+		{
+			Description: "Remove the netsh port-forward to the WSL distribution",
+			Command: fmt.Sprintf("netsh interface portproxy delete v4tov4 listenport=%d listenaddress=127.0.0.1",
+				wslForwardedAPIPort),
+			Reversible: true,
+		},
+	}
+}
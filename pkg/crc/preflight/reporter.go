@@ -0,0 +1,153 @@
+package preflight
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// SchemaVersion is bumped whenever the shape of CheckResult changes in a
+// way that isn't backward compatible, so machine consumers can detect
+// unsupported versions instead of guessing from missing fields.
+const SchemaVersion = 1
+
+// CheckStatus is the outcome of running a single preflight check.
+type CheckStatus string
+
+const (
+	StatusPass    CheckStatus = "pass"
+	StatusFail    CheckStatus = "fail"
+	StatusFixed   CheckStatus = "fixed"
+	StatusSkipped CheckStatus = "skipped"
+)
+
+// CheckResult is the structured record emitted for a single Check when
+// preflight is run with a Reporter, e.g. via `crc setup --output json`.
+type CheckResult struct {
+	ConfigKeySuffix string        `json:"configKeySuffix"`
+	Description     string        `json:"description"`
+	Status          CheckStatus   `json:"status"`
+	Error           string        `json:"error,omitempty"`
+	ErrorCategory   ErrorCategory `json:"errorCategory,omitempty"`
+	DurationMs      int64         `json:"durationMs"`
+	FixApplied      bool          `json:"fixApplied"`
+}
+
+// Reporter receives one CheckResult per Check as RunChecks executes
+// them. Implementations must be safe to call synchronously from a
+// single goroutine; RunChecks never calls a Reporter concurrently.
+type Reporter interface {
+	Report(result CheckResult)
+}
+
+// jsonDocument is the shape written by NewJSONReporter: a single JSON
+// document containing every result, written once all checks have run.
+type jsonDocument struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Checks        []CheckResult `json:"checks"`
+}
+
+// JSONReporter buffers results and writes them as a single JSON document
+// when Flush is called, for `--output json`.
+type JSONReporter struct {
+	w       io.Writer
+	results []CheckResult
+}
+
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{w: w}
+}
+
+func (r *JSONReporter) Report(result CheckResult) {
+	r.results = append(r.results, result)
+}
+
+// Flush writes the buffered results as a single JSON document.
+func (r *JSONReporter) Flush() error {
+	return json.NewEncoder(r.w).Encode(jsonDocument{
+		SchemaVersion: SchemaVersion,
+		Checks:        r.results,
+	})
+}
+
+// ndjsonResult is a single newline-delimited JSON event, tagged with the
+// schema version since, unlike JSONReporter, there is no wrapping
+// document to carry it once per run.
+type ndjsonResult struct {
+	SchemaVersion int `json:"schemaVersion"`
+	CheckResult
+}
+
+// NDJSONReporter writes one JSON object per line as each check
+// completes, for `--output ndjson`, so a long-running `crc setup` can be
+// consumed incrementally by CI or an IDE plugin.
+type NDJSONReporter struct {
+	enc *json.Encoder
+}
+
+func NewNDJSONReporter(w io.Writer) *NDJSONReporter {
+	return &NDJSONReporter{enc: json.NewEncoder(w)}
+}
+
+func (r *NDJSONReporter) Report(result CheckResult) {
+	// Encoding errors here would mean the output stream is broken, in
+	// which case there's nothing useful RunChecks could do with the
+	// error either, so it's dropped the same way a failed log line
+	// would be.
+	_ = r.enc.Encode(ndjsonResult{SchemaVersion: SchemaVersion, CheckResult: result})
+}
+
+// RunChecks runs checks in order, reporting one CheckResult per check to
+// reporter. `crc setup` runs its checks through it for every --output
+// format; the Reporter decides whether results become log lines or
+// machine-readable records.
+func RunChecks(checks []Check, reporter Reporter) []CheckResult {
+	results := make([]CheckResult, 0, len(checks))
+	for _, c := range checks {
+		result := runCheck(c)
+		reporter.Report(result)
+		results = append(results, result)
+	}
+	return results
+}
+
+func runCheck(c Check) CheckResult {
+	result := CheckResult{
+		ConfigKeySuffix: c.configKeySuffix,
+		Description:     c.checkDescription,
+	}
+
+	if c.check == nil {
+		result.Status = StatusSkipped
+		return result
+	}
+
+	start := time.Now()
+	err := c.check()
+	if err == nil {
+		result.Status = StatusPass
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	if c.fix == nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+		result.ErrorCategory = categoryOf(err)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	if fixErr := c.fix(); fixErr != nil {
+		result.Status = StatusFail
+		result.Error = fixErr.Error()
+		result.ErrorCategory = categoryOf(fixErr)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result
+	}
+
+	result.Status = StatusFixed
+	result.FixApplied = true
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
@@ -0,0 +1,54 @@
+package preflight
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONReporterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewJSONReporter(&buf)
+	r.Report(CheckResult{ConfigKeySuffix: "check-a", Status: StatusPass})
+	r.Report(CheckResult{ConfigKeySuffix: "check-b", Status: StatusFail, Error: "boom"})
+
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush() = %v", err)
+	}
+
+	var doc jsonDocument
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if doc.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", doc.SchemaVersion, SchemaVersion)
+	}
+	if len(doc.Checks) != 2 {
+		t.Fatalf("len(Checks) = %d, want 2", len(doc.Checks))
+	}
+	if doc.Checks[1].Error != "boom" {
+		t.Errorf("Checks[1].Error = %q, want %q", doc.Checks[1].Error, "boom")
+	}
+}
+
+func TestNDJSONReporterWritesOneLinePerResult(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewNDJSONReporter(&buf)
+	r.Report(CheckResult{ConfigKeySuffix: "check-a", Status: StatusPass})
+	r.Report(CheckResult{ConfigKeySuffix: "check-b", Status: StatusFixed, FixApplied: true})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var result ndjsonResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("line %d: unmarshal: %v", i, err)
+		}
+		if result.SchemaVersion != SchemaVersion {
+			t.Errorf("line %d: SchemaVersion = %d, want %d", i, result.SchemaVersion, SchemaVersion)
+		}
+	}
+}
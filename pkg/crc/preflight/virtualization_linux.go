@@ -0,0 +1,164 @@
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// cloudHypervisorVendors maps the strings found in DMI vendor/product
+// fields to a human-readable hypervisor name, so a user running crc
+// inside a cloud instance gets told which hypervisor they're nested
+// under instead of a bare "virtualization not available".
+var cloudHypervisorVendors = map[string]string{
+	"kvm":            "KVM",
+	"qemu":           "QEMU/KVM",
+	"microsoft corp": "Hyper-V",
+	"vmware":         "VMware",
+	"xen":            "Xen",
+	"google":         "Google Compute Engine (KVM)",
+	"amazon ec2":     "Amazon EC2 (KVM/Nitro)",
+}
+
+// checkVirtualizationEnabled checks that the CPU exposes VMX/SVM, and
+// additionally detects whether crc itself is running nested inside
+// another hypervisor (common on cloud instances), in which case it also
+// requires that nested virtualization is enabled for that hypervisor's
+// KVM module.
+func checkVirtualizationEnabled() error {
+	cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return err
+	}
+	flags := string(cpuinfo)
+
+	// Check for a nested-virt cloud hypervisor before the VMX/SVM flag
+	// check below: a hypervisor that hides nested virtualization from
+	// its guest (the default on GCE/EC2 etc.) also hides the vmx/svm
+	// flag from /proc/cpuinfo, so detecting it has to rely on
+	// /sys/hypervisor/type and DMI strings instead.
+	hypervisor, nested := detectHypervisor("/")
+	if hypervisor != "" && !nested {
+		if module := nestedKvmModule(flags); module != "" {
+			return newCheckError(CategoryNotConfigured,
+				"crc is running inside %s and nested virtualization is not enabled for %s; "+
+					"on cloud providers this often requires recreating the instance with nested virtualization turned on",
+				hypervisor, module)
+		}
+		// Running nested under an unrecognized vendor without a known
+		// AMD/Intel CPU; assume it's fine rather than blocking setup on
+		// a guess.
+	}
+
+	if !strings.Contains(flags, "vmx") && !strings.Contains(flags, "svm") {
+		return newCheckError(CategoryNotConfigured, "VMX/SVM is not enabled in the BIOS/firmware")
+	}
+	return nil
+}
+
+func fixVirtualizationEnabled() error {
+	cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return err
+	}
+	module := nestedKvmModule(string(cpuinfo))
+	if module == "" {
+		return newCheckError(CategoryUnsupported, "could not determine which KVM module (kvm_intel/kvm_amd) to enable nested virtualization for")
+	}
+
+	if _, _, err := crcos.RunWithPrivilege("unload "+module, "modprobe", "-r", module); err != nil {
+		return err
+	}
+	if _, _, err := crcos.RunWithPrivilege("reload "+module+" with nested=1", "modprobe", module, "nested=1"); err != nil {
+		return err
+	}
+	persist := fmt.Sprintf("echo 'options %s nested=1' > /etc/modprobe.d/kvm.conf", module)
+	if _, _, err := crcos.RunWithPrivilege("persist nested=1", "sh", "-c", persist); err != nil {
+		return err
+	}
+	return nil
+}
+
+// detectHypervisor reports the name of the hypervisor crc is running
+// under, if any, and whether nested virtualization is already enabled
+// for it. It consults /sys/hypervisor/type, the "hypervisor" flag in
+// /proc/cpuinfo, and DMI vendor/product strings, in that order. root is
+// prepended to every path read, so tests can point it at a fake sysfs
+// tree instead of the real one.
+func detectHypervisor(root string) (name string, nested bool) {
+	if hvType, err := ioutil.ReadFile(filepath.Join(root, "/sys/hypervisor/type")); err == nil {
+		name = strings.TrimSpace(string(hvType))
+	}
+
+	if name == "" {
+		if cpuinfo, err := ioutil.ReadFile(filepath.Join(root, "/proc/cpuinfo")); err == nil {
+			if strings.Contains(string(cpuinfo), "hypervisor") {
+				name = dmiHypervisorVendor(root)
+			}
+		}
+	}
+
+	if name == "" {
+		return "", false
+	}
+
+	return name, nestedVirtEnabled(root)
+}
+
+// dmiHypervisorVendor inspects DMI vendor/product strings for a known
+// cloud hypervisor signature.
+func dmiHypervisorVendor(root string) string {
+	for _, path := range []string{
+		"/sys/class/dmi/id/sys_vendor",
+		"/sys/class/dmi/id/product_name",
+		"/sys/class/dmi/id/bios_vendor",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			continue
+		}
+		value := strings.ToLower(strings.TrimSpace(string(data)))
+		for needle, vendor := range cloudHypervisorVendors {
+			if strings.Contains(value, needle) {
+				return vendor
+			}
+		}
+	}
+	return "unknown hypervisor"
+}
+
+// nestedKvmModule returns the kvm_intel/kvm_amd module name matching
+// the CPU's vendor_id field, or "" if it's neither Intel nor AMD. This
+// reads vendor_id rather than the vmx/svm flags because a hypervisor
+// with nested virtualization disabled hides those flags from its guest
+// entirely, while vendor_id is always present.
+func nestedKvmModule(cpuinfo string) string {
+	switch {
+	case strings.Contains(cpuinfo, "GenuineIntel"):
+		return "kvm_intel"
+	case strings.Contains(cpuinfo, "AuthenticAMD"):
+		return "kvm_amd"
+	default:
+		return ""
+	}
+}
+
+// nestedVirtEnabled reads the nested virtualization parameter of
+// whichever of kvm_intel/kvm_amd is loaded.
+func nestedVirtEnabled(root string) bool {
+	for _, path := range []string{
+		"/sys/module/kvm_intel/parameters/nested",
+		"/sys/module/kvm_amd/parameters/nested",
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		return value == "Y" || value == "1"
+	}
+	return false
+}
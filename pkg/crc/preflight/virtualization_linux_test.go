@@ -0,0 +1,81 @@
+package preflight
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNestedKvmModule(t *testing.T) {
+	tests := []struct {
+		name    string
+		cpuinfo string
+		want    string
+	}{
+		{"intel", "vendor_id\t: GenuineIntel\nflags\t\t: fpu vme", "kvm_intel"},
+		{"amd", "vendor_id\t: AuthenticAMD\n", "kvm_amd"},
+		{"unknown", "vendor_id\t: Bochs\n", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nestedKvmModule(tt.cpuinfo); got != tt.want {
+				t.Errorf("nestedKvmModule(%q) = %q, want %q", tt.cpuinfo, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDmiHypervisorVendor(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sys/class/dmi/id/sys_vendor"), "Google\n")
+
+	if got, want := dmiHypervisorVendor(root), "Google Compute Engine (KVM)"; got != want {
+		t.Errorf("dmiHypervisorVendor() = %q, want %q", got, want)
+	}
+}
+
+func TestDmiHypervisorVendorUnknown(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sys/class/dmi/id/sys_vendor"), "Acme Corp\n")
+
+	if got, want := dmiHypervisorVendor(root), "unknown hypervisor"; got != want {
+		t.Errorf("dmiHypervisorVendor() = %q, want %q", got, want)
+	}
+}
+
+func TestDetectHypervisor(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "sys/hypervisor/type"), "kvm\n")
+	writeFile(t, filepath.Join(root, "sys/module/kvm_intel/parameters/nested"), "Y\n")
+
+	name, nested := detectHypervisor(root)
+	if name != "kvm" {
+		t.Errorf("name = %q, want %q", name, "kvm")
+	}
+	if !nested {
+		t.Error("nested = false, want true")
+	}
+}
+
+func TestDetectHypervisorNone(t *testing.T) {
+	root := t.TempDir()
+
+	name, nested := detectHypervisor(root)
+	if name != "" {
+		t.Errorf("name = %q, want empty", name)
+	}
+	if nested {
+		t.Error("nested = true, want false")
+	}
+}